@@ -0,0 +1,37 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/lib/pq"
+)
+
+// DBOptions holds the connection parameters needed to open
+// a connection pool to the Gira Postgres database.
+type DBOptions struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	DBName   string
+	SSLMode  string
+}
+
+// NewDB opens a connection pool to the database described by opts
+// and verifies the connection is alive.
+func NewDB(opts *DBOptions) (*sql.DB, error) {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		opts.Host, opts.Port, opts.User, opts.Password, opts.DBName, opts.SSLMode)
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"database/sql"
+	"embed"
+
+	goose "github.com/pressly/goose/v3"
+	_ "modernc.org/sqlite"
+)
+
+//go:embed sql/*.sql
+var migrationsFS embed.FS
+
+// sqlDir is the directory, relative to this package's embedded FS,
+// containing the migrations shipped with the binary.
+const sqlDir = "sql"
+
+// Directory returns the directory to pass to Migrate/MigrateWithDriver
+// in order to run the migrations embedded in this binary.
+func Directory() (string, error) {
+	return sqlDir, nil
+}
+
+// Driver opens the *sql.DB that migrations run against, and reports the
+// goose dialect matching it.
+type Driver interface {
+	Open() (*sql.DB, error)
+	Dialect() string
+}
+
+// PostgresDriver opens a connection pool to a real Postgres database.
+type PostgresDriver struct {
+	Opts *DBOptions
+}
+
+// Open opens a connection pool to d.Opts.
+func (d *PostgresDriver) Open() (*sql.DB, error) {
+	return NewDB(d.Opts)
+}
+
+// Dialect returns "postgres".
+func (d *PostgresDriver) Dialect() string {
+	return "postgres"
+}
+
+// SQLiteDriver opens a fresh in-memory sqlite database. It lets tests
+// exercise the migration runner without a real Postgres instance.
+type SQLiteDriver struct{}
+
+// Open opens a new in-memory sqlite database.
+func (SQLiteDriver) Open() (*sql.DB, error) {
+	return sql.Open("sqlite", ":memory:")
+}
+
+// Dialect returns "sqlite3".
+func (SQLiteDriver) Dialect() string {
+	return "sqlite3"
+}
+
+// Migrate runs cmd (one of up, up-to, down, down-to, redo, status or
+// version) against the Postgres database described by opts, using the
+// migrations in dir.
+func Migrate(opts *DBOptions, dir, cmd string, args ...string) error {
+	return MigrateWithDriver(&PostgresDriver{Opts: opts}, dir, cmd, args...)
+}
+
+// MigrateWithDriver runs cmd against the database opened by driver,
+// using the migrations in dir. It is the pluggable form of Migrate,
+// letting callers (e.g. tests) target a driver other than Postgres.
+func MigrateWithDriver(driver Driver, dir, cmd string, args ...string) error {
+	db, err := driver.Open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := goose.SetDialect(driver.Dialect()); err != nil {
+		return err
+	}
+
+	// Only look inside the embedded FS for the migrations shipped with
+	// this binary. Any other dir (e.g. from InitFromDirectory) is a
+	// real path that must be read from the OS filesystem.
+	if dir == sqlDir {
+		goose.SetBaseFS(migrationsFS)
+	} else {
+		goose.SetBaseFS(nil)
+	}
+
+	return goose.Run(cmd, db, dir, args...)
+}
+
+// Reset drops and re-applies every migration in dir, returning the
+// database to a clean state. It is used by integration tests that need
+// a fresh schema per test.
+func Reset(opts *DBOptions, dir string) error {
+	if err := Migrate(opts, dir, "reset"); err != nil {
+		return err
+	}
+	return Migrate(opts, dir, "up")
+}
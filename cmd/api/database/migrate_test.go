@@ -0,0 +1,87 @@
+package database_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asankov/gira/cmd/api/database"
+
+	_ "modernc.org/sqlite"
+)
+
+// fileSQLiteDriver is a Driver backed by a real on-disk sqlite file,
+// rather than database.SQLiteDriver's fresh-per-Open :memory: database.
+// MigrateWithDriver opens and closes a *sql.DB on every call, so a test
+// exercising more than one command (up, then status, then down, ...)
+// needs state to persist across those calls.
+type fileSQLiteDriver struct {
+	path string
+}
+
+func (d fileSQLiteDriver) Open() (*sql.DB, error) {
+	return sql.Open("sqlite", d.path)
+}
+
+func (d fileSQLiteDriver) Dialect() string {
+	return "sqlite3"
+}
+
+// writeTestMigrations writes a single, dialect-neutral migration (the
+// real migrations under sql/*.sql use Postgres-only syntax such as
+// SERIAL and BYTEA) to a temp directory and returns its path.
+func writeTestMigrations(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	migration := `-- +goose Up
+CREATE TABLE widgets (
+    id   INTEGER PRIMARY KEY,
+    name TEXT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE widgets;
+`
+	if err := os.WriteFile(filepath.Join(dir, "00001_create_widgets_table.sql"), []byte(migration), 0o600); err != nil {
+		t.Fatalf("writing test migration: %v", err)
+	}
+	return dir
+}
+
+func TestMigrateWithDriver(t *testing.T) {
+	dir := writeTestMigrations(t)
+	driver := fileSQLiteDriver{path: filepath.Join(t.TempDir(), "test.db")}
+
+	if err := database.MigrateWithDriver(driver, dir, "up"); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+
+	db, err := driver.Open()
+	if err != nil {
+		t.Fatalf("opening driver: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`INSERT INTO widgets (id, name) VALUES (1, 'sprocket')`); err != nil {
+		t.Fatalf("expected widgets table to exist after up, got: %v", err)
+	}
+
+	if err := database.MigrateWithDriver(driver, dir, "status"); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+
+	if err := database.MigrateWithDriver(driver, dir, "redo"); err != nil {
+		t.Fatalf("redo: %v", err)
+	}
+	if _, err := db.Query(`SELECT id FROM widgets WHERE id = 1`); err != nil {
+		t.Fatalf("expected widgets table to exist after redo, got: %v", err)
+	}
+
+	if err := database.MigrateWithDriver(driver, dir, "down"); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO widgets (id, name) VALUES (2, 'cog')`); err == nil {
+		t.Fatal("expected widgets table to be dropped after down")
+	}
+}
@@ -0,0 +1,89 @@
+// Gira API.
+//
+// @title        Gira API
+// @version      1.0
+// @description  REST API for managing Gira users and games.
+//
+// @BasePath  /
+//
+// @securityDefinitions.apikey  TokenAuth
+// @in                          header
+// @name                        x-auth-token
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+
+	_ "github.com/asankov/gira/docs"
+
+	"github.com/asankov/gira/cmd/api/database"
+	"github.com/asankov/gira/cmd/api/server"
+	"github.com/asankov/gira/pkg/models/postgres"
+)
+
+func main() {
+	var (
+		addr       = flag.String("addr", ":4000", "HTTP network address")
+		dbHost     = flag.String("db-host", "localhost", "Database host")
+		dbPort     = flag.Int("db-port", 5432, "Database port")
+		dbUser     = flag.String("db-user", "gira", "Database user")
+		dbPassword = flag.String("db-password", "", "Database password")
+		dbName     = flag.String("db-name", "gira", "Database name")
+		jwtSecret  = flag.String("jwt-secret", "", "Secret used to sign access tokens")
+		smtpHost   = flag.String("smtp-host", "", "SMTP host used to send account emails")
+		smtpPort   = flag.Int("smtp-port", 587, "SMTP port")
+		smtpUser   = flag.String("smtp-username", "", "SMTP username")
+		smtpPass   = flag.String("smtp-password", "", "SMTP password")
+		smtpFrom   = flag.String("smtp-from", "", "Address account emails are sent from")
+	)
+	flag.Parse()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	if *jwtSecret == "" {
+		log.Error("-jwt-secret must not be empty")
+		os.Exit(1)
+	}
+	if *smtpHost == "" {
+		log.Error("-smtp-host must not be empty - no Mailer can be configured")
+		os.Exit(1)
+	}
+
+	dbOpts := &database.DBOptions{
+		Host:     *dbHost,
+		Port:     *dbPort,
+		User:     *dbUser,
+		Password: *dbPassword,
+		DBName:   *dbName,
+		SSLMode:  "disable",
+	}
+	if err := database.Init(dbOpts); err != nil {
+		log.Error("error running migrations", "error", err)
+		os.Exit(1)
+	}
+
+	db, err := database.NewDB(dbOpts)
+	if err != nil {
+		log.Error("error connecting to database", "error", err)
+		os.Exit(1)
+	}
+
+	srv := server.NewServer(log, &postgres.UserModel{DB: db}, &server.JWTAuthenticator{Secret: []byte(*jwtSecret)}, server.WithSwaggerUI())
+	srv.LoginLimiter = server.NewAccountLoginLimiter(&postgres.LoginAttemptModel{DB: db}, server.DefaultAccountLoginLimiterConfig())
+	srv.Mailer = &server.SMTPMailer{Config: server.SMTPMailerConfig{
+		Host:     *smtpHost,
+		Port:     *smtpPort,
+		Username: *smtpUser,
+		Password: *smtpPass,
+		From:     *smtpFrom,
+	}}
+
+	log.Info("starting server", "addr", *addr)
+	if err := http.ListenAndServe(*addr, srv); err != nil {
+		log.Error("server stopped", "error", err)
+		os.Exit(1)
+	}
+}
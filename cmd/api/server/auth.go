@@ -0,0 +1,56 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/asankov/gira/pkg/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// accessTokenTTL is how long an access token issued by JWTAuthenticator
+// remains valid for.
+const accessTokenTTL = 15 * time.Minute
+
+// Authenticator is the interface for issuing tokens for an
+// authenticated user.
+type Authenticator interface {
+	// NewTokenForUser issues a short-lived access token for user.
+	NewTokenForUser(user *models.User) (string, error)
+	// NewRefreshToken generates a new opaque, long-lived refresh token.
+	// The caller is responsible for persisting it (see UserModel.SaveRefreshToken).
+	NewRefreshToken() (string, error)
+}
+
+// JWTAuthenticator is an Authenticator that issues signed JWTs as access tokens.
+type JWTAuthenticator struct {
+	Secret []byte
+}
+
+// NewTokenForUser issues a JWT, signed with a.Secret, that identifies user
+// and expires after accessTokenTTL.
+func (a *JWTAuthenticator) NewTokenForUser(user *models.User) (string, error) {
+	claims := jwt.MapClaims{
+		"sub": user.ID,
+		"exp": time.Now().Add(accessTokenTTL).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	return token.SignedString(a.Secret)
+}
+
+// NewRefreshToken generates a cryptographically random, URL-safe refresh token.
+func (a *JWTAuthenticator) NewRefreshToken() (string, error) {
+	return newRawToken()
+}
+
+// newRawToken returns a cryptographically random, URL-safe token,
+// suitable for refresh/reset/verification tokens.
+func newRawToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,268 @@
+package server
+
+import (
+	"hash/fnv"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// LoginLimiter guards /users/login against both credential stuffing
+// (many requests from one source) and brute-forcing a single account
+// (many attempts against one email).
+type LoginLimiter interface {
+	// Allow reports whether a login attempt from r, for email, may
+	// proceed. If not, retryAfter is how long the caller should wait
+	// before trying again.
+	Allow(r *http.Request, email string) (ok bool, retryAfter time.Duration)
+
+	// RecordFailure registers a failed authentication attempt for
+	// email, locking it out (with escalating backoff) once the
+	// failure threshold is reached.
+	RecordFailure(email string) error
+
+	// RecordSuccess clears email's failure count and any lockout.
+	RecordSuccess(email string) error
+}
+
+// NoopLoginLimiter never rate-limits or locks out. It is the default
+// used by tests that don't exercise this behaviour.
+type NoopLoginLimiter struct{}
+
+// Allow always allows the request.
+func (NoopLoginLimiter) Allow(*http.Request, string) (bool, time.Duration) { return true, 0 }
+
+// RecordFailure is a no-op.
+func (NoopLoginLimiter) RecordFailure(string) error { return nil }
+
+// RecordSuccess is a no-op.
+func (NoopLoginLimiter) RecordSuccess(string) error { return nil }
+
+// LoginAttemptStore persists the per-account lockout state behind
+// AccountLoginLimiter. pkg/models/postgres.LoginAttemptModel is the
+// production implementation.
+type LoginAttemptStore interface {
+	// Locked reports whether email is currently locked out, and if so,
+	// until when.
+	Locked(email string) (locked bool, until time.Time, err error)
+
+	// RecordFailure registers a failed attempt for email. Once
+	// threshold consecutive failures have occurred within window, it
+	// locks the account out, doubling the previous lockout (up to max)
+	// each time it re-locks.
+	RecordFailure(email string, threshold int, window, base, max time.Duration) (locked bool, until time.Time, err error)
+
+	// RecordSuccess clears email's failure count and lockout.
+	RecordSuccess(email string) error
+}
+
+// AccountLoginLimiterConfig configures an AccountLoginLimiter's rate
+// limit and lockout thresholds.
+type AccountLoginLimiterConfig struct {
+	// RPS and Burst configure the per-IP token bucket.
+	RPS   float64
+	Burst int
+
+	// TrustedProxies lists the addresses allowed to set
+	// X-Forwarded-For. A request is only attributed to its forwarded
+	// address if RemoteAddr's host is in this list.
+	TrustedProxies []string
+
+	// FailureThreshold is the number of consecutive Authenticate
+	// failures, within Window, that locks an account out.
+	FailureThreshold int
+	Window           time.Duration
+
+	// BaseLockout is the duration of the first lockout; each
+	// subsequent lockout doubles the previous one, up to MaxLockout.
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+
+	// IdleLimiterTTL is how long a per-IP bucket may sit unused before
+	// AccountLoginLimiter evicts it. This bounds the memory the IP
+	// limiter shards use under churn from many distinct source IPs.
+	IdleLimiterTTL time.Duration
+}
+
+// DefaultAccountLoginLimiterConfig returns sensible defaults: 1
+// request/second per IP with a burst of 5, locking an account out for a
+// minute (doubling up to 30 minutes) after 5 consecutive failures
+// within 15 minutes. Per-IP buckets idle for 10 minutes are evicted.
+func DefaultAccountLoginLimiterConfig() AccountLoginLimiterConfig {
+	return AccountLoginLimiterConfig{
+		RPS:              1,
+		Burst:            5,
+		FailureThreshold: 5,
+		Window:           15 * time.Minute,
+		BaseLockout:      time.Minute,
+		MaxLockout:       30 * time.Minute,
+		IdleLimiterTTL:   10 * time.Minute,
+	}
+}
+
+// numIPLimiterShards is the number of shards AccountLoginLimiter splits
+// its per-IP buckets across, so concurrent logins from different IPs
+// don't contend on one mutex.
+const numIPLimiterShards = 32
+
+// ipLimiterEntry is a single IP's token bucket, plus when it was last
+// used so idle entries can be evicted.
+type ipLimiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// ipLimiterShard is one of AccountLoginLimiter's shards: an
+// independently-locked slice of the overall per-IP bucket map.
+type ipLimiterShard struct {
+	mu       sync.Mutex
+	limiters map[string]*ipLimiterEntry
+}
+
+// AccountLoginLimiter is the production LoginLimiter. It rate-limits by
+// source IP with a sharded in-memory token bucket per address, and
+// delegates account lockout to a persistent LoginAttemptStore.
+type AccountLoginLimiter struct {
+	Store  LoginAttemptStore
+	Config AccountLoginLimiterConfig
+
+	trustedProxies map[string]struct{}
+	shards         [numIPLimiterShards]*ipLimiterShard
+}
+
+// NewAccountLoginLimiter constructs an AccountLoginLimiter backed by
+// store, and starts a background goroutine that periodically evicts
+// per-IP buckets idle for longer than cfg.IdleLimiterTTL.
+func NewAccountLoginLimiter(store LoginAttemptStore, cfg AccountLoginLimiterConfig) *AccountLoginLimiter {
+	trusted := make(map[string]struct{}, len(cfg.TrustedProxies))
+	for _, addr := range cfg.TrustedProxies {
+		trusted[addr] = struct{}{}
+	}
+
+	l := &AccountLoginLimiter{
+		Store:          store,
+		Config:         cfg,
+		trustedProxies: trusted,
+	}
+	for i := range l.shards {
+		l.shards[i] = &ipLimiterShard{limiters: make(map[string]*ipLimiterEntry)}
+	}
+
+	if cfg.IdleLimiterTTL > 0 {
+		go l.evictIdleLimitersPeriodically(cfg.IdleLimiterTTL)
+	}
+
+	return l
+}
+
+// Allow implements LoginLimiter.
+func (l *AccountLoginLimiter) Allow(r *http.Request, email string) (bool, time.Duration) {
+	if !l.allowIP(clientIP(r, l.trustedProxies)) {
+		return false, l.ipRetryAfter()
+	}
+
+	locked, until, err := l.Store.Locked(email)
+	if err != nil {
+		return false, 0
+	}
+	if locked {
+		return false, time.Until(until)
+	}
+	return true, 0
+}
+
+// RecordFailure implements LoginLimiter.
+func (l *AccountLoginLimiter) RecordFailure(email string) error {
+	_, _, err := l.Store.RecordFailure(email, l.Config.FailureThreshold, l.Config.Window, l.Config.BaseLockout, l.Config.MaxLockout)
+	return err
+}
+
+// RecordSuccess implements LoginLimiter.
+func (l *AccountLoginLimiter) RecordSuccess(email string) error {
+	return l.Store.RecordSuccess(email)
+}
+
+// shardFor returns the shard that owns ip's bucket.
+func (l *AccountLoginLimiter) shardFor(ip string) *ipLimiterShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(ip))
+	return l.shards[h.Sum32()%numIPLimiterShards]
+}
+
+// allowIP consumes a token from ip's bucket, creating one (with the
+// configured RPS/Burst) on first use.
+func (l *AccountLoginLimiter) allowIP(ip string) bool {
+	shard := l.shardFor(ip)
+
+	shard.mu.Lock()
+	entry, ok := shard.limiters[ip]
+	if !ok {
+		entry = &ipLimiterEntry{limiter: rate.NewLimiter(rate.Limit(l.Config.RPS), l.Config.Burst)}
+		shard.limiters[ip] = entry
+	}
+	entry.lastSeen = time.Now()
+	limiter := entry.limiter
+	shard.mu.Unlock()
+
+	return limiter.Allow()
+}
+
+// evictIdleLimitersPeriodically runs evictIdleLimiters every ttl/2 until
+// the process exits.
+func (l *AccountLoginLimiter) evictIdleLimitersPeriodically(ttl time.Duration) {
+	ticker := time.NewTicker(ttl / 2)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		l.evictIdleLimiters(ttl)
+	}
+}
+
+// evictIdleLimiters removes every per-IP bucket that hasn't been used
+// in the last ttl, across all shards.
+func (l *AccountLoginLimiter) evictIdleLimiters(ttl time.Duration) {
+	cutoff := time.Now().Add(-ttl)
+	for _, shard := range l.shards {
+		shard.mu.Lock()
+		for ip, entry := range shard.limiters {
+			if entry.lastSeen.Before(cutoff) {
+				delete(shard.limiters, ip)
+			}
+		}
+		shard.mu.Unlock()
+	}
+}
+
+// ipRetryAfter is a conservative estimate of how long an IP-rate-limited
+// caller should wait before its next token is available.
+func (l *AccountLoginLimiter) ipRetryAfter() time.Duration {
+	if l.Config.RPS <= 0 {
+		return time.Second
+	}
+	return time.Duration(float64(time.Second) / l.Config.RPS)
+}
+
+// clientIP returns the address a request should be attributed to: the
+// right-most entry of X-Forwarded-For if RemoteAddr's host is a trusted
+// proxy, otherwise RemoteAddr itself.
+func clientIP(r *http.Request, trustedProxies map[string]struct{}) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if _, trusted := trustedProxies[host]; !trusted {
+		return host
+	}
+
+	fwd := r.Header.Get("X-Forwarded-For")
+	if fwd == "" {
+		return host
+	}
+	hops := strings.Split(fwd, ",")
+	return strings.TrimSpace(hops[len(hops)-1])
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"fmt"
+	"net/smtp"
+)
+
+// Mailer sends the transactional emails required by the account
+// lifecycle (password reset, email verification).
+type Mailer interface {
+	SendPasswordResetEmail(to, token string) error
+	SendVerificationEmail(to, token string) error
+}
+
+// NoopMailer is a Mailer that does nothing. It is useful for local
+// development and tests, where no real emails should be sent.
+type NoopMailer struct{}
+
+// SendPasswordResetEmail does nothing and always succeeds.
+func (NoopMailer) SendPasswordResetEmail(to, token string) error { return nil }
+
+// SendVerificationEmail does nothing and always succeeds.
+func (NoopMailer) SendVerificationEmail(to, token string) error { return nil }
+
+// SMTPMailerConfig holds the connection parameters needed to send mail
+// through a real SMTP relay.
+type SMTPMailerConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// From is the address transactional emails are sent from.
+	From string
+}
+
+// SMTPMailer is a Mailer that sends real emails through an SMTP relay,
+// authenticating with Config.Username/Password.
+type SMTPMailer struct {
+	Config SMTPMailerConfig
+}
+
+// SendPasswordResetEmail emails token to the given address as a
+// password reset token.
+func (m *SMTPMailer) SendPasswordResetEmail(to, token string) error {
+	return m.send(to, "Reset your Gira password",
+		fmt.Sprintf("Use this token to reset your password: %s", token))
+}
+
+// SendVerificationEmail emails token to the given address as an
+// account-verification token.
+func (m *SMTPMailer) SendVerificationEmail(to, token string) error {
+	return m.send(to, "Verify your Gira account",
+		fmt.Sprintf("Use this token to verify your account: %s", token))
+}
+
+// send delivers a plain-text email with the given subject and body to
+// to, authenticating against m.Config.
+func (m *SMTPMailer) send(to, subject, body string) error {
+	addr := fmt.Sprintf("%s:%d", m.Config.Host, m.Config.Port)
+	auth := smtp.PlainAuth("", m.Config.Username, m.Config.Password, m.Config.Host)
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n",
+		m.Config.From, to, subject, body)
+
+	return smtp.SendMail(addr, auth, m.Config.From, []string{to}, []byte(msg))
+}
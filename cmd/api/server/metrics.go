@@ -0,0 +1,53 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the Prometheus collectors exposed by the Server at /metrics.
+type Metrics struct {
+	RequestsTotal   *prometheus.CounterVec
+	RequestDuration *prometheus.HistogramVec
+
+	registry *prometheus.Registry
+}
+
+// NewMetrics creates a Metrics with its own registry, so that concurrent
+// tests don't collide on prometheus' global DefaultRegisterer.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		RequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests handled, by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		RequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "http_request_duration_seconds",
+			Help: "HTTP request duration in seconds, by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		registry: prometheus.NewRegistry(),
+	}
+	m.registry.MustRegister(m.RequestsTotal, m.RequestDuration)
+
+	return m
+}
+
+// Observe records one HTTP request against the collectors.
+func (m *Metrics) Observe(method, route string, status int, duration time.Duration) {
+	labels := prometheus.Labels{
+		"method": method,
+		"route":  route,
+		"status": strconv.Itoa(status),
+	}
+	m.RequestsTotal.With(labels).Inc()
+	m.RequestDuration.With(labels).Observe(duration.Seconds())
+}
+
+// Handler returns the http.Handler to be mounted at /metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
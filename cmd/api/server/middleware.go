@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// requestIDHeader is the header used to propagate the request ID, both
+// inbound (so callers can supply their own) and outbound.
+const requestIDHeader = "X-Request-ID"
+
+type contextKey string
+
+const requestIDContextKey contextKey = "requestID"
+
+// requestIDMiddleware assigns a request ID to r, reusing the inbound
+// X-Request-ID header if the caller already supplied one, and makes it
+// available to handlers via requestIDFromContext.
+func (s *Server) requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		ctx := context.WithValue(r.Context(), requestIDContextKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// observabilityMiddleware logs every request as structured JSON, records
+// it against s.Metrics, and reports 5xx responses (including recovered
+// panics) to s.Sentry, tagged with the request ID.
+func (s *Server) observabilityMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		requestID := requestIDFromContext(r.Context())
+
+		var panicErr error
+		func() {
+			defer func() {
+				if rerr := recover(); rerr != nil {
+					panicErr = fmt.Errorf("panic: %v", rerr)
+					rec.status = http.StatusInternalServerError
+					http.Error(rec, "internal server error", http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+		}()
+
+		duration := time.Since(start)
+		s.Metrics.Observe(r.Method, r.URL.Path, rec.status, duration)
+
+		s.Log.Info("handled request",
+			"request_id", requestID,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration_ms", duration.Milliseconds(),
+		)
+
+		switch {
+		case panicErr != nil:
+			s.Sentry.CaptureError(panicErr, map[string]string{"request_id": requestID})
+		case rec.status >= http.StatusInternalServerError:
+			s.Sentry.CaptureError(fmt.Errorf("request failed with status %d", rec.status), map[string]string{"request_id": requestID})
+		}
+	})
+}
+
+// statusRecorder wraps a http.ResponseWriter to capture the status code
+// written to it, so observabilityMiddleware can log and record it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
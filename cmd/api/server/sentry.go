@@ -0,0 +1,34 @@
+package server
+
+import (
+	"github.com/getsentry/sentry-go"
+)
+
+// Sentry is the interface for reporting errors to an error-tracking
+// service. It exists so tests can inject a no-op implementation instead
+// of depending on a real Sentry project.
+type Sentry interface {
+	// CaptureError reports err, annotated with tags (e.g. the request ID).
+	CaptureError(err error, tags map[string]string)
+}
+
+// NoopSentry is a Sentry that discards every error. It is the default
+// used by NewServer, and is useful for local development and tests.
+type NoopSentry struct{}
+
+// CaptureError does nothing.
+func (NoopSentry) CaptureError(err error, tags map[string]string) {}
+
+// SentryReporter is a Sentry that forwards errors to a real Sentry project
+// via the sentry-go SDK, which must already be initialized (sentry.Init).
+type SentryReporter struct{}
+
+// CaptureError reports err to Sentry, attaching tags to the event.
+func (SentryReporter) CaptureError(err error, tags map[string]string) {
+	sentry.WithScope(func(scope *sentry.Scope) {
+		for k, v := range tags {
+			scope.SetTag(k, v)
+		}
+		sentry.CaptureException(err)
+	})
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/asankov/gira/pkg/models"
+	httpSwagger "github.com/swaggo/http-swagger"
+)
+
+// UserModel is the interface for managing users and their
+// credentials (passwords, refresh tokens, reset/verification tokens).
+type UserModel interface {
+	Insert(user *models.User) (*models.User, error)
+	Authenticate(email, password string) (*models.User, error)
+
+	SaveRefreshToken(userID, rawToken string) error
+	RefreshToken(rawToken, newRawToken string) (*models.User, error)
+	RevokeRefreshToken(rawToken string) error
+
+	CreatePasswordResetToken(email string) (string, error)
+	ResetPassword(rawToken, newPassword string) error
+
+	CreateVerificationToken(userID string) (string, error)
+	VerifyUser(rawToken string) error
+}
+
+// Server is the HTTP server for the Gira API. It wires together
+// the dependencies needed by the handlers and implements http.Handler.
+type Server struct {
+	Log *slog.Logger
+
+	UserModel     UserModel
+	Authenticator Authenticator
+	Mailer        Mailer
+	Metrics       *Metrics
+	Sentry        Sentry
+	LoginLimiter  LoginLimiter
+
+	swaggerUI bool
+}
+
+// Option configures optional Server behaviour, for use with NewServer.
+type Option func(*Server)
+
+// WithSwaggerUI mounts the Swagger UI (and its JSON spec) at /swagger/*.
+func WithSwaggerUI() Option {
+	return func(s *Server) { s.swaggerUI = true }
+}
+
+// NewServer constructs a Server wired with sensible defaults - a no-op
+// Mailer, Sentry reporter and LoginLimiter, and a fresh Metrics registry
+// - any of which the caller can override afterwards.
+func NewServer(log *slog.Logger, userModel UserModel, authenticator Authenticator, opts ...Option) *Server {
+	s := &Server{
+		Log:           log,
+		UserModel:     userModel,
+		Authenticator: authenticator,
+		Mailer:        NoopMailer{},
+		Sentry:        NoopSentry{},
+		LoginLimiter:  NoopLoginLimiter{},
+		Metrics:       NewMetrics(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ServeHTTP implements http.Handler. It runs every request through the
+// observability middleware chain before routing it to a handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requestIDMiddleware(s.observabilityMiddleware(s.routes())).ServeHTTP(w, r)
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/users", s.userCreateHandler)
+	mux.HandleFunc("/users/login", s.userLoginHandler)
+	mux.HandleFunc("/users/refresh", s.userRefreshHandler)
+	mux.HandleFunc("/users/logout", s.userLogoutHandler)
+	mux.HandleFunc("/users/password/forgot", s.userPasswordForgotHandler)
+	mux.HandleFunc("/users/password/reset", s.userPasswordResetHandler)
+	mux.HandleFunc("/users/verify", s.userVerifyHandler)
+	mux.Handle("/metrics", s.Metrics.Handler())
+
+	if s.swaggerUI {
+		mux.Handle("/swagger/", httpSwagger.WrapHandler)
+	}
+
+	return mux
+}
+
+func (s *Server) respondJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if v != nil {
+		_ = json.NewEncoder(w).Encode(v)
+	}
+}
+
+func (s *Server) badRequest(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+// tooManyRequests responds with 429, telling the caller how long to
+// wait (rounded up to the nearest second) before retrying.
+func (s *Server) tooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	http.Error(w, "too many requests", http.StatusTooManyRequests)
+}
+
+func (s *Server) internalError(w http.ResponseWriter, err error) {
+	if s.Log != nil {
+		s.Log.Error(err.Error())
+	}
+	http.Error(w, "internal server error", http.StatusInternalServerError)
+}
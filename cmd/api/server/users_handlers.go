@@ -0,0 +1,390 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/asankov/gira/pkg/models"
+	"github.com/asankov/gira/pkg/models/postgres"
+)
+
+var errInvalidRequest = errors.New("invalid request")
+
+// userCreateHandler godoc
+// @Summary      Create a user
+// @Description  Registers a new user account and emails it a verification token.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        user  body      models.User  true  "New user"
+// @Success      200   {object}  models.User
+// @Failure      400   {string}  string  "invalid request, or email/username already taken"
+// @Failure      500   {string}  string  "internal server error"
+// @Router       /users [post]
+func (s *Server) userCreateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+
+	var u models.User
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		s.badRequest(w, err)
+		return
+	}
+	if err := validateNewUser(&u); err != nil {
+		s.badRequest(w, err)
+		return
+	}
+
+	created, err := s.UserModel.Insert(&u)
+	if err != nil {
+		switch {
+		case errors.Is(err, postgres.ErrEmailAlreadyExists), errors.Is(err, postgres.ErrUsernameAlreadyExists):
+			s.badRequest(w, err)
+		default:
+			s.internalError(w, err)
+		}
+		return
+	}
+
+	verificationToken, err := s.UserModel.CreateVerificationToken(created.ID)
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+	if err := s.Mailer.SendVerificationEmail(created.Email, verificationToken); err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	s.respondJSON(w, http.StatusOK, created)
+}
+
+// validateNewUser checks that u is a well-formed request to create a new
+// user: all of username, email and password must be set, and none of the
+// server-assigned fields (ID, HashedPassword) may be supplied by the client.
+func validateNewUser(u *models.User) error {
+	if u.Username == "" || u.Email == "" || u.Password == "" {
+		return errInvalidRequest
+	}
+	if u.ID != "" || u.HashedPassword != nil {
+		return errInvalidRequest
+	}
+	return nil
+}
+
+// userLoginHandler godoc
+// @Summary      Log in
+// @Description  Authenticates a user and issues an access token and a refresh token.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        credentials  body      models.User  true  "Email and password"
+// @Success      200          {object}  models.UserResponse
+// @Failure      400          {string}  string  "invalid request"
+// @Failure      401          {string}  string  "invalid credentials"
+// @Failure      429          {string}  string  "too many attempts - account locked or rate limited"
+// @Failure      500          {string}  string  "internal server error"
+// @Router       /users/login [post]
+func (s *Server) userLoginHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+
+	var u models.User
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		s.badRequest(w, err)
+		return
+	}
+	if u.Email == "" || u.Password == "" {
+		s.badRequest(w, errInvalidRequest)
+		return
+	}
+
+	if ok, retryAfter := s.LoginLimiter.Allow(r, u.Email); !ok {
+		s.tooManyRequests(w, retryAfter)
+		return
+	}
+
+	user, err := s.UserModel.Authenticate(u.Email, u.Password)
+	if err != nil {
+		if errors.Is(err, postgres.ErrInvalidCredentials) {
+			if err := s.LoginLimiter.RecordFailure(u.Email); err != nil {
+				s.internalError(w, err)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		s.internalError(w, err)
+		return
+	}
+	if err := s.LoginLimiter.RecordSuccess(u.Email); err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	accessToken, err := s.Authenticator.NewTokenForUser(user)
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	refreshToken, err := s.Authenticator.NewRefreshToken()
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+	if err := s.UserModel.SaveRefreshToken(user.ID, refreshToken); err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	user.HashedPassword = nil
+	s.respondJSON(w, http.StatusOK, &models.UserResponse{
+		User:         *user,
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+	})
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// userRefreshHandler godoc
+// @Summary      Refresh an access token
+// @Description  Validates req.RefreshToken, rotates it and issues a new access token for the user it belongs to.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      refreshRequest  true  "Refresh token"
+// @Success      200      {object}  models.UserResponse
+// @Failure      400      {string}  string  "invalid request"
+// @Failure      401      {string}  string  "refresh token unknown or expired"
+// @Failure      500      {string}  string  "internal server error"
+// @Router       /users/refresh [post]
+func (s *Server) userRefreshHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, err)
+		return
+	}
+	if req.RefreshToken == "" {
+		s.badRequest(w, errInvalidRequest)
+		return
+	}
+
+	newRefreshToken, err := s.Authenticator.NewRefreshToken()
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	user, err := s.UserModel.RefreshToken(req.RefreshToken, newRefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, postgres.ErrTokenNotFound), errors.Is(err, postgres.ErrTokenExpired):
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		default:
+			s.internalError(w, err)
+		}
+		return
+	}
+
+	accessToken, err := s.Authenticator.NewTokenForUser(user)
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	user.HashedPassword = nil
+	s.respondJSON(w, http.StatusOK, &models.UserResponse{
+		User:         *user,
+		Token:        accessToken,
+		RefreshToken: newRefreshToken,
+	})
+}
+
+// userLogoutHandler godoc
+// @Summary      Log out
+// @Description  Revokes the supplied refresh token, so it can no longer be exchanged for a new access token.
+// @Tags         users
+// @Accept       json
+// @Param        request  body  refreshRequest  true  "Refresh token"
+// @Success      204
+// @Failure      400  {string}  string  "invalid request, or refresh token unknown"
+// @Failure      500  {string}  string  "internal server error"
+// @Router       /users/logout [post]
+func (s *Server) userLogoutHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, err)
+		return
+	}
+	if req.RefreshToken == "" {
+		s.badRequest(w, errInvalidRequest)
+		return
+	}
+
+	if err := s.UserModel.RevokeRefreshToken(req.RefreshToken); err != nil {
+		if errors.Is(err, postgres.ErrTokenNotFound) {
+			s.badRequest(w, err)
+			return
+		}
+		s.internalError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// userPasswordForgotHandler godoc
+// @Summary      Request a password reset
+// @Description  Emails a password reset token to req.Email, if an account with that email exists. Never reveals whether the email is registered.
+// @Tags         users
+// @Accept       json
+// @Param        request  body  forgotPasswordRequest  true  "Email"
+// @Success      204
+// @Failure      400  {string}  string  "invalid request"
+// @Failure      500  {string}  string  "internal server error"
+// @Router       /users/password/forgot [post]
+func (s *Server) userPasswordForgotHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+
+	var req forgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, err)
+		return
+	}
+	if req.Email == "" {
+		s.badRequest(w, errInvalidRequest)
+		return
+	}
+
+	token, err := s.UserModel.CreatePasswordResetToken(req.Email)
+	if err != nil {
+		if errors.Is(err, postgres.ErrTokenNotFound) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		s.internalError(w, err)
+		return
+	}
+
+	if err := s.Mailer.SendPasswordResetEmail(req.Email, token); err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// userPasswordResetHandler godoc
+// @Summary      Reset a password
+// @Description  Consumes req.Token and sets req.Password as the requesting user's new password.
+// @Tags         users
+// @Accept       json
+// @Param        request  body  resetPasswordRequest  true  "Reset token and new password"
+// @Success      204
+// @Failure      400  {string}  string  "invalid request"
+// @Failure      401  {string}  string  "token unknown or expired"
+// @Failure      500  {string}  string  "internal server error"
+// @Router       /users/password/reset [post]
+func (s *Server) userPasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+
+	var req resetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, err)
+		return
+	}
+	if req.Token == "" || req.Password == "" {
+		s.badRequest(w, errInvalidRequest)
+		return
+	}
+
+	if err := s.UserModel.ResetPassword(req.Token, req.Password); err != nil {
+		switch {
+		case errors.Is(err, postgres.ErrTokenNotFound), errors.Is(err, postgres.ErrTokenExpired):
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		default:
+			s.internalError(w, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type verifyRequest struct {
+	Token string `json:"token"`
+}
+
+// userVerifyHandler godoc
+// @Summary      Verify an account
+// @Description  Consumes req.Token, marking the corresponding account as verified.
+// @Tags         users
+// @Accept       json
+// @Param        request  body  verifyRequest  true  "Verification token"
+// @Success      204
+// @Failure      400  {string}  string  "invalid request"
+// @Failure      401  {string}  string  "token unknown or expired"
+// @Failure      500  {string}  string  "internal server error"
+// @Router       /users/verify [post]
+func (s *Server) userVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+
+	var req verifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, err)
+		return
+	}
+	if req.Token == "" {
+		s.badRequest(w, errInvalidRequest)
+		return
+	}
+
+	if err := s.UserModel.VerifyUser(req.Token); err != nil {
+		switch {
+		case errors.Is(err, postgres.ErrTokenNotFound), errors.Is(err, postgres.ErrTokenExpired):
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		default:
+			s.internalError(w, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,16 +2,19 @@ package server
 
 import (
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/asankov/gira/internal/fixtures"
 	"github.com/asankov/gira/pkg/models"
 	"github.com/asankov/gira/pkg/models/postgres"
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 var (
@@ -23,10 +26,22 @@ var (
 )
 
 func setupUsersServer(u UserModel, a *fixtures.AuthenticatorMock) *Server {
+	return setupUsersServerWithMailer(u, a, nil)
+}
+
+func setupUsersServerWithMailer(u UserModel, a *fixtures.AuthenticatorMock, m *fixtures.MailerMock) *Server {
+	var mailer Mailer
+	if m != nil {
+		mailer = m
+	}
 	return &Server{
-		Log:           log.New(os.Stdout, "", 0),
+		Log:           slog.New(slog.NewJSONHandler(os.Stdout, nil)),
 		UserModel:     u,
 		Authenticator: a,
+		Mailer:        mailer,
+		Metrics:       NewMetrics(),
+		Sentry:        NoopSentry{},
+		LoginLimiter:  NoopLoginLimiter{},
 	}
 }
 
@@ -35,12 +50,21 @@ func TestUserCreate(t *testing.T) {
 
 	userModel := fixtures.NewUserModelMock(ctrl)
 	authenticator := fixtures.NewAuthenticatorMock(ctrl)
-	srv := setupUsersServer(userModel, authenticator)
+	mailer := fixtures.NewMailerMock(ctrl)
+	srv := setupUsersServerWithMailer(userModel, authenticator, mailer)
 
 	userModel.EXPECT().
 		Insert(&expectedUser).
 		Return(&expectedUser, nil)
 
+	verificationToken := "verification_token"
+	userModel.EXPECT().
+		CreateVerificationToken(expectedUser.ID).
+		Return(verificationToken, nil)
+	mailer.EXPECT().
+		SendVerificationEmail(expectedUser.Email, verificationToken).
+		Return(nil)
+
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest(http.MethodPost, "/users", fixtures.Marshall(t, expectedUser))
 	srv.ServeHTTP(w, r)
@@ -163,6 +187,12 @@ func TestUserCreateDBError(t *testing.T) {
 			if got != expected {
 				t.Fatalf("Got (%d) for status code, expected (%d)", got, expected)
 			}
+
+			wantMetric := float64(1)
+			gotMetric := testutil.ToFloat64(srv.Metrics.RequestsTotal.WithLabelValues(http.MethodPost, "/users", fmt.Sprint(c.expectedCode)))
+			if gotMetric != wantMetric {
+				t.Errorf("Got (%v) for http_requests_total, expected (%v)", gotMetric, wantMetric)
+			}
 		})
 	}
 }
@@ -175,15 +205,25 @@ func TestUserLogin(t *testing.T) {
 
 	srv := setupUsersServer(userModel, authenticatorMock)
 
+	authenticatedUser := expectedUser
+	authenticatedUser.HashedPassword = []byte("$2a$10$notarealbcrypthash")
 	userModel.EXPECT().
 		Authenticate(expectedUser.Email, expectedUser.Password).
-		Return(&expectedUser, nil)
+		Return(&authenticatedUser, nil)
 
 	token := "my_test_token"
 	authenticatorMock.EXPECT().
-		NewTokenForUser(&expectedUser).
+		NewTokenForUser(&authenticatedUser).
 		Return(token, nil)
 
+	refreshToken := "my_test_refresh_token"
+	authenticatorMock.EXPECT().
+		NewRefreshToken().
+		Return(refreshToken, nil)
+	userModel.EXPECT().
+		SaveRefreshToken(expectedUser.ID, refreshToken).
+		Return(nil)
+
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest(http.MethodPost, "/users/login", fixtures.Marshall(t, expectedUser))
 	srv.ServeHTTP(w, r)
@@ -197,6 +237,12 @@ func TestUserLogin(t *testing.T) {
 	if userResponse.Token != token {
 		t.Fatalf(`Got ("%s") for token, expected ("%s")`, userResponse.Token, token)
 	}
+	if userResponse.RefreshToken != refreshToken {
+		t.Fatalf(`Got ("%s") for refresh token, expected ("%s")`, userResponse.RefreshToken, refreshToken)
+	}
+	if userResponse.User.HashedPassword != nil {
+		t.Fatalf("expected hashed_password to be stripped from the login response, got (%v)", userResponse.User.HashedPassword)
+	}
 }
 
 func TestUserLoginValidationError(t *testing.T) {
@@ -242,8 +288,10 @@ func TestUserLoginValidationError(t *testing.T) {
 
 func TestUserLoginServiceError(t *testing.T) {
 	testCases := []struct {
-		name  string
-		setup func(u *fixtures.UserModelMock, a *fixtures.AuthenticatorMock)
+		name         string
+		setup        func(u *fixtures.UserModelMock, a *fixtures.AuthenticatorMock)
+		limiterSetup func(l *fixtures.LoginLimiterMock)
+		expectedCode int
 	}{
 		{
 			name: "UserModel.Authenticate fails",
@@ -252,6 +300,7 @@ func TestUserLoginServiceError(t *testing.T) {
 					Authenticate(expectedUser.Email, expectedUser.Password).
 					Return(nil, errors.New("user not found"))
 			},
+			expectedCode: http.StatusInternalServerError,
 		},
 		{
 			name: "Authenticator.NewTokenForUser fails",
@@ -264,6 +313,25 @@ func TestUserLoginServiceError(t *testing.T) {
 					NewTokenForUser(&expectedUser).
 					Return("", errors.New("intentional error"))
 			},
+			limiterSetup: func(l *fixtures.LoginLimiterMock) {
+				l.EXPECT().
+					Allow(gomock.Any(), expectedUser.Email).
+					Return(true, time.Duration(0))
+				l.EXPECT().
+					RecordSuccess(expectedUser.Email).
+					Return(nil)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:  "Locked account",
+			setup: func(u *fixtures.UserModelMock, a *fixtures.AuthenticatorMock) {},
+			limiterSetup: func(l *fixtures.LoginLimiterMock) {
+				l.EXPECT().
+					Allow(gomock.Any(), expectedUser.Email).
+					Return(false, 30*time.Second)
+			},
+			expectedCode: http.StatusTooManyRequests,
 		},
 	}
 	for _, testCase := range testCases {
@@ -272,19 +340,336 @@ func TestUserLoginServiceError(t *testing.T) {
 
 			userModel := fixtures.NewUserModelMock(ctrl)
 			authenticatorMock := fixtures.NewAuthenticatorMock(ctrl)
-
+			limiterMock := fixtures.NewLoginLimiterMock(ctrl)
+
+			if testCase.limiterSetup != nil {
+				testCase.limiterSetup(limiterMock)
+			} else {
+				limiterMock.EXPECT().
+					Allow(gomock.Any(), expectedUser.Email).
+					Return(true, time.Duration(0))
+			}
 			testCase.setup(userModel, authenticatorMock)
 
 			srv := setupUsersServer(userModel, authenticatorMock)
+			srv.LoginLimiter = limiterMock
 
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodPost, "/users/login", fixtures.Marshall(t, expectedUser))
 			srv.ServeHTTP(w, r)
 
-			got, expected := w.Code, http.StatusInternalServerError
+			got, expected := w.Code, testCase.expectedCode
+			if got != expected {
+				t.Fatalf("Got (%d) for status code, expected (%d)", got, expected)
+			}
+
+			if testCase.expectedCode == http.StatusTooManyRequests {
+				if retryAfter := w.Header().Get("Retry-After"); retryAfter == "" {
+					t.Error("expected a Retry-After header")
+				}
+			}
+		})
+	}
+}
+func TestUserRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	userModel := fixtures.NewUserModelMock(ctrl)
+	authenticatorMock := fixtures.NewAuthenticatorMock(ctrl)
+	srv := setupUsersServer(userModel, authenticatorMock)
+
+	oldRefreshToken := "old_refresh_token"
+	newRefreshToken := "new_refresh_token"
+	authenticatorMock.EXPECT().
+		NewRefreshToken().
+		Return(newRefreshToken, nil)
+	userModel.EXPECT().
+		RefreshToken(oldRefreshToken, newRefreshToken).
+		Return(&expectedUser, nil)
+
+	token := "my_test_token"
+	authenticatorMock.EXPECT().
+		NewTokenForUser(&expectedUser).
+		Return(token, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/users/refresh", fixtures.Marshall(t, refreshRequest{RefreshToken: oldRefreshToken}))
+	srv.ServeHTTP(w, r)
+
+	got, expected := w.Code, http.StatusOK
+	if got != expected {
+		t.Fatalf("Got (%d) for status code, expected (%d)", got, expected)
+	}
+	var userResponse models.UserResponse
+	fixtures.Decode(t, w.Body, &userResponse)
+	if userResponse.Token != token {
+		t.Fatalf(`Got ("%s") for token, expected ("%s")`, userResponse.Token, token)
+	}
+	if userResponse.RefreshToken != newRefreshToken {
+		t.Fatalf(`Got ("%s") for refresh token, expected ("%s")`, userResponse.RefreshToken, newRefreshToken)
+	}
+}
+
+func TestUserRefreshValidationError(t *testing.T) {
+	srv := setupUsersServer(nil, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/users/refresh", fixtures.Marshall(t, refreshRequest{}))
+	srv.ServeHTTP(w, r)
+
+	got, expected := w.Code, http.StatusBadRequest
+	if got != expected {
+		t.Fatalf("Got (%d) for status code, expected (%d)", got, expected)
+	}
+}
+
+func TestUserRefreshServiceError(t *testing.T) {
+	testCases := []struct {
+		name         string
+		dbError      error
+		expectedCode int
+	}{
+		{
+			name:         "Unknown token",
+			dbError:      postgres.ErrTokenNotFound,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "Expired token",
+			dbError:      postgres.ErrTokenExpired,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "Unknown error",
+			dbError:      errors.New("unknown error"),
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			userModel := fixtures.NewUserModelMock(ctrl)
+			authenticatorMock := fixtures.NewAuthenticatorMock(ctrl)
+			srv := setupUsersServer(userModel, authenticatorMock)
+
+			authenticatorMock.EXPECT().
+				NewRefreshToken().
+				Return("new_refresh_token", nil)
+			userModel.EXPECT().
+				RefreshToken("old_refresh_token", "new_refresh_token").
+				Return(nil, c.dbError)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/users/refresh", fixtures.Marshall(t, refreshRequest{RefreshToken: "old_refresh_token"}))
+			srv.ServeHTTP(w, r)
+
+			got, expected := w.Code, c.expectedCode
+			if got != expected {
+				t.Fatalf("Got (%d) for status code, expected (%d)", got, expected)
+			}
+		})
+	}
+}
+
+func TestUserLogout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	userModel := fixtures.NewUserModelMock(ctrl)
+	srv := setupUsersServer(userModel, nil)
+
+	refreshToken := "my_refresh_token"
+	userModel.EXPECT().
+		RevokeRefreshToken(refreshToken).
+		Return(nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/users/logout", fixtures.Marshall(t, refreshRequest{RefreshToken: refreshToken}))
+	srv.ServeHTTP(w, r)
+
+	got, expected := w.Code, http.StatusNoContent
+	if got != expected {
+		t.Fatalf("Got (%d) for status code, expected (%d)", got, expected)
+	}
+}
+
+func TestUserLogoutValidationError(t *testing.T) {
+	srv := setupUsersServer(nil, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/users/logout", fixtures.Marshall(t, refreshRequest{}))
+	srv.ServeHTTP(w, r)
+
+	got, expected := w.Code, http.StatusBadRequest
+	if got != expected {
+		t.Fatalf("Got (%d) for status code, expected (%d)", got, expected)
+	}
+}
+
+func TestUserPasswordForgot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	userModel := fixtures.NewUserModelMock(ctrl)
+	mailer := fixtures.NewMailerMock(ctrl)
+	srv := setupUsersServerWithMailer(userModel, nil, mailer)
+
+	resetToken := "reset_token"
+	userModel.EXPECT().
+		CreatePasswordResetToken(expectedUser.Email).
+		Return(resetToken, nil)
+	mailer.EXPECT().
+		SendPasswordResetEmail(expectedUser.Email, resetToken).
+		Return(nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/users/password/forgot", fixtures.Marshall(t, forgotPasswordRequest{Email: expectedUser.Email}))
+	srv.ServeHTTP(w, r)
+
+	got, expected := w.Code, http.StatusNoContent
+	if got != expected {
+		t.Fatalf("Got (%d) for status code, expected (%d)", got, expected)
+	}
+}
+
+func TestUserPasswordForgotUnknownEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	userModel := fixtures.NewUserModelMock(ctrl)
+	srv := setupUsersServer(userModel, nil)
+
+	userModel.EXPECT().
+		CreatePasswordResetToken(expectedUser.Email).
+		Return("", postgres.ErrTokenNotFound)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/users/password/forgot", fixtures.Marshall(t, forgotPasswordRequest{Email: expectedUser.Email}))
+	srv.ServeHTTP(w, r)
+
+	got, expected := w.Code, http.StatusNoContent
+	if got != expected {
+		t.Fatalf("Got (%d) for status code, expected (%d)", got, expected)
+	}
+}
+
+func TestUserPasswordReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	userModel := fixtures.NewUserModelMock(ctrl)
+	srv := setupUsersServer(userModel, nil)
+
+	resetToken := "reset_token"
+	newPassword := "n3wP@ssword"
+	userModel.EXPECT().
+		ResetPassword(resetToken, newPassword).
+		Return(nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/users/password/reset", fixtures.Marshall(t, resetPasswordRequest{Token: resetToken, Password: newPassword}))
+	srv.ServeHTTP(w, r)
+
+	got, expected := w.Code, http.StatusNoContent
+	if got != expected {
+		t.Fatalf("Got (%d) for status code, expected (%d)", got, expected)
+	}
+}
+
+func TestUserPasswordResetServiceError(t *testing.T) {
+	testCases := []struct {
+		name         string
+		dbError      error
+		expectedCode int
+	}{
+		{
+			name:         "Unknown token",
+			dbError:      postgres.ErrTokenNotFound,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "Expired token",
+			dbError:      postgres.ErrTokenExpired,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			userModel := fixtures.NewUserModelMock(ctrl)
+			srv := setupUsersServer(userModel, nil)
+
+			userModel.EXPECT().
+				ResetPassword("reset_token", "n3wP@ssword").
+				Return(c.dbError)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/users/password/reset", fixtures.Marshall(t, resetPasswordRequest{Token: "reset_token", Password: "n3wP@ssword"}))
+			srv.ServeHTTP(w, r)
+
+			got, expected := w.Code, c.expectedCode
 			if got != expected {
 				t.Fatalf("Got (%d) for status code, expected (%d)", got, expected)
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestUserVerify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	userModel := fixtures.NewUserModelMock(ctrl)
+	srv := setupUsersServer(userModel, nil)
+
+	verificationToken := "verification_token"
+	userModel.EXPECT().
+		VerifyUser(verificationToken).
+		Return(nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/users/verify", fixtures.Marshall(t, verifyRequest{Token: verificationToken}))
+	srv.ServeHTTP(w, r)
+
+	got, expected := w.Code, http.StatusNoContent
+	if got != expected {
+		t.Fatalf("Got (%d) for status code, expected (%d)", got, expected)
+	}
+}
+
+func TestUserVerifyServiceError(t *testing.T) {
+	testCases := []struct {
+		name         string
+		dbError      error
+		expectedCode int
+	}{
+		{
+			name:         "Unknown token",
+			dbError:      postgres.ErrTokenNotFound,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "Expired token",
+			dbError:      postgres.ErrTokenExpired,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			userModel := fixtures.NewUserModelMock(ctrl)
+			srv := setupUsersServer(userModel, nil)
+
+			userModel.EXPECT().
+				VerifyUser("verification_token").
+				Return(c.dbError)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/users/verify", fixtures.Marshall(t, verifyRequest{Token: "verification_token"}))
+			srv.ServeHTTP(w, r)
+
+			got, expected := w.Code, c.expectedCode
+			if got != expected {
+				t.Fatalf("Got (%d) for status code, expected (%d)", got, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+//go:build integration
+
+package server_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/asankov/gira/cmd/api/server"
+	"github.com/asankov/gira/internal/fixtures"
+	"github.com/asankov/gira/internal/testutil"
+	"github.com/asankov/gira/pkg/models"
+	"github.com/asankov/gira/pkg/models/postgres"
+)
+
+// capturingMailer records the tokens it is asked to send, standing in
+// for the emails a real Mailer would deliver.
+type capturingMailer struct {
+	verificationToken string
+	resetToken        string
+}
+
+func (m *capturingMailer) SendVerificationEmail(_, token string) error {
+	m.verificationToken = token
+	return nil
+}
+
+func (m *capturingMailer) SendPasswordResetEmail(_, token string) error {
+	m.resetToken = token
+	return nil
+}
+
+func newIntegrationServer(t *testing.T) (*server.Server, *capturingMailer) {
+	t.Helper()
+
+	db := testutil.SetupDB(t)
+	mailer := &capturingMailer{}
+
+	srv := server.NewServer(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		&postgres.UserModel{DB: db},
+		&server.JWTAuthenticator{Secret: []byte("integration-test-secret")},
+	)
+	srv.Mailer = mailer
+
+	return srv, mailer
+}
+
+// TestUsersIntegration drives the full create -> verify -> login round
+// trip against a real Postgres-backed UserModel, producing an access
+// token that a caller could actually use.
+func TestUsersIntegration(t *testing.T) {
+	srv, mailer := newIntegrationServer(t)
+
+	user := models.User{Username: "integration", Email: "integration@test.com", Password: "Sup3rSecret!"}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/users", fixtures.Marshall(t, user))
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("create: got (%d), expected (%d): %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if mailer.verificationToken == "" {
+		t.Fatal("expected a verification token to have been emailed")
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/users/verify", fixtures.Marshall(t, verifyRequest{Token: mailer.verificationToken}))
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("verify: got (%d), expected (%d): %s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/users/login", fixtures.Marshall(t, user))
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: got (%d), expected (%d): %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var userResponse models.UserResponse
+	fixtures.Decode(t, w.Body, &userResponse)
+	if userResponse.Token == "" {
+		t.Fatal("expected a usable access token")
+	}
+	if userResponse.RefreshToken == "" {
+		t.Fatal("expected a refresh token")
+	}
+}
+
+// TestUserCreateDuplicateEmailIntegration asserts that the real
+// database's unique constraint surfaces as postgres.ErrEmailAlreadyExists,
+// returned to the client as a 400.
+func TestUserCreateDuplicateEmailIntegration(t *testing.T) {
+	srv, _ := newIntegrationServer(t)
+
+	first := models.User{Username: "dup-email-1", Email: "dup@test.com", Password: "Sup3rSecret!"}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/users", fixtures.Marshall(t, first))
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("first create: got (%d), expected (%d): %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	second := models.User{Username: "dup-email-2", Email: "dup@test.com", Password: "Sup3rSecret!"}
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/users", fixtures.Marshall(t, second))
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("got (%d), expected (%d): %s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+}
+
+// TestUserCreateDuplicateUsernameIntegration asserts the same for the
+// username unique constraint.
+func TestUserCreateDuplicateUsernameIntegration(t *testing.T) {
+	srv, _ := newIntegrationServer(t)
+
+	first := models.User{Username: "dup-username", Email: "dup-username-1@test.com", Password: "Sup3rSecret!"}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/users", fixtures.Marshall(t, first))
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("first create: got (%d), expected (%d): %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	second := models.User{Username: "dup-username", Email: "dup-username-2@test.com", Password: "Sup3rSecret!"}
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/users", fixtures.Marshall(t, second))
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("got (%d), expected (%d): %s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+}
+
+type verifyRequest struct {
+	Token string `json:"token"`
+}
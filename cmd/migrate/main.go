@@ -0,0 +1,49 @@
+// Command migrate runs Gira's database migrations.
+//
+// Usage:
+//
+//	migrate -cmd up
+//	migrate -cmd up-to -- 3
+//	migrate -cmd down
+//	migrate -cmd status
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/asankov/gira/cmd/api/database"
+)
+
+func main() {
+	var (
+		cmd        = flag.String("cmd", "status", "Migration command: up, up-to, down, down-to, redo, status or version")
+		dbHost     = flag.String("db-host", "localhost", "Database host")
+		dbPort     = flag.Int("db-port", 5432, "Database port")
+		dbUser     = flag.String("db-user", "gira", "Database user")
+		dbPassword = flag.String("db-password", "", "Database password")
+		dbName     = flag.String("db-name", "gira", "Database name")
+	)
+	flag.Parse()
+
+	opts := &database.DBOptions{
+		Host:     *dbHost,
+		Port:     *dbPort,
+		User:     *dbUser,
+		Password: *dbPassword,
+		DBName:   *dbName,
+		SSLMode:  "disable",
+	}
+
+	dir, err := database.Directory()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err := database.Migrate(opts, dir, *cmd, flag.Args()...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,31 @@
+// Package fixtures contains test helpers and mocks, shared across the
+// server package's test suites.
+package fixtures
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+// Marshall encodes v as JSON and returns a reader over the result,
+// suitable for use as an httptest.NewRequest body.
+func Marshall(t *testing.T, v interface{}) io.Reader {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("error marshalling %v: %v", v, err)
+	}
+	return bytes.NewReader(b)
+}
+
+// Decode decodes the JSON body of r into v.
+func Decode(t *testing.T, r io.Reader, v interface{}) {
+	t.Helper()
+
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		t.Fatalf("error decoding body: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+// Package testutil provides helpers for integration tests that need a
+// real Postgres database to run against.
+package testutil
+
+import (
+	"database/sql"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/asankov/gira/cmd/api/database"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// SetupDB returns a *sql.DB connected to a fresh Postgres schema with
+// every migration applied.
+//
+// If TEST_DATABASE_URL is set, that database is reused; otherwise a
+// disposable Postgres container is started via dockertest. Either way,
+// the schema is reset before the test runs, and the connection (plus,
+// for dockertest, the container) is torn down via t.Cleanup.
+func SetupDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	opts := dbOptions(t)
+
+	dir, err := database.Directory()
+	if err != nil {
+		t.Fatalf("could not resolve migrations directory: %v", err)
+	}
+	if err := database.Reset(opts, dir); err != nil {
+		t.Fatalf("could not reset schema: %v", err)
+	}
+
+	db, err := database.NewDB(opts)
+	if err != nil {
+		t.Fatalf("could not connect to database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func dbOptions(t *testing.T) *database.DBOptions {
+	t.Helper()
+
+	if dsn := os.Getenv("TEST_DATABASE_URL"); dsn != "" {
+		opts, err := parseDSN(dsn)
+		if err != nil {
+			t.Fatalf("could not parse TEST_DATABASE_URL: %v", err)
+		}
+		return opts
+	}
+
+	return startContainer(t)
+}
+
+func parseDSN(dsn string) (*database.DBOptions, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return nil, err
+	}
+
+	password, _ := u.User.Password()
+	sslMode := u.Query().Get("sslmode")
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	return &database.DBOptions{
+		Host:     u.Hostname(),
+		Port:     port,
+		User:     u.User.Username(),
+		Password: password,
+		DBName:   strings.TrimPrefix(u.Path, "/"),
+		SSLMode:  sslMode,
+	}, nil
+}
+
+// startContainer starts a disposable Postgres container via dockertest
+// and returns the options needed to connect to it.
+func startContainer(t *testing.T) *database.DBOptions {
+	t.Helper()
+
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		t.Fatalf("could not connect to docker: %v", err)
+	}
+
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: "postgres",
+		Tag:        "15-alpine",
+		Env: []string{
+			"POSTGRES_USER=gira",
+			"POSTGRES_PASSWORD=gira",
+			"POSTGRES_DB=gira_test",
+		},
+	}, func(hc *docker.HostConfig) {
+		hc.AutoRemove = true
+	})
+	if err != nil {
+		t.Fatalf("could not start postgres container: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := pool.Purge(resource); err != nil {
+			t.Logf("could not purge postgres container: %v", err)
+		}
+	})
+
+	port, err := strconv.Atoi(resource.GetPort("5432/tcp"))
+	if err != nil {
+		t.Fatalf("could not parse container port: %v", err)
+	}
+
+	opts := &database.DBOptions{
+		Host:     "localhost",
+		Port:     port,
+		User:     "gira",
+		Password: "gira",
+		DBName:   "gira_test",
+		SSLMode:  "disable",
+	}
+
+	if err := pool.Retry(func() error {
+		db, err := database.NewDB(opts)
+		if err != nil {
+			return err
+		}
+		return db.Close()
+	}); err != nil {
+		t.Fatalf("could not connect to postgres container: %v", err)
+	}
+
+	return opts
+}
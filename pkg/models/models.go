@@ -1,8 +1,53 @@
 package models
 
+import "time"
+
 // Game is the representation of a game
 // in the database.
 type Game struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
-}
\ No newline at end of file
+}
+
+// User is the representation of a user
+// in the database.
+type User struct {
+	ID       string `json:"id,omitempty"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+
+	// Password is the plain-text password, supplied by the client.
+	// It is never persisted and never returned in a response.
+	Password string `json:"password,omitempty"`
+	// HashedPassword is the bcrypt hash of Password, as stored in the database.
+	// It must never be set by the client.
+	HashedPassword []byte `json:"hashed_password,omitempty"`
+
+	// Verified reports whether the user has confirmed their email address.
+	// Unverified users cannot log in.
+	Verified bool `json:"verified"`
+}
+
+// UserResponse is the response, returned by the
+// user-facing endpoints that issue tokens (e.g. login, refresh).
+type UserResponse struct {
+	User User `json:"user"`
+
+	// Token is the short-lived access token, used to authenticate
+	// subsequent requests via the x-auth-token header.
+	Token string `json:"token"`
+	// RefreshToken is the long-lived token, used to obtain a new
+	// access token from /users/refresh once Token expires.
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// RefreshToken is the representation of a refresh token
+// in the database. The value stored in the DB is a hash of
+// the raw token returned to the client.
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
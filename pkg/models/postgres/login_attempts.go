@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// LoginAttemptModel wraps a sql.DB connection pool and tracks failed
+// login attempts per email, so server.AccountLoginLimiter can lock an
+// account out after repeated failures.
+type LoginAttemptModel struct {
+	DB *sql.DB
+}
+
+// Locked reports whether email is currently locked out, and if so,
+// until when.
+func (m *LoginAttemptModel) Locked(email string) (bool, time.Time, error) {
+	var lockedUntil sql.NullTime
+
+	stmt := `SELECT locked_until FROM login_attempts WHERE email = $1`
+	if err := m.DB.QueryRow(stmt, email).Scan(&lockedUntil); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, time.Time{}, nil
+		}
+		return false, time.Time{}, err
+	}
+
+	if !lockedUntil.Valid || !lockedUntil.Time.After(time.Now()) {
+		return false, time.Time{}, nil
+	}
+	return true, lockedUntil.Time, nil
+}
+
+// RecordFailure registers a failed attempt for email. Once threshold
+// consecutive failures have occurred within window, it locks the
+// account out, doubling the previous lockout (up to max) each time it
+// re-locks.
+func (m *LoginAttemptModel) RecordFailure(email string, threshold int, window, base, max time.Duration) (bool, time.Time, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	defer tx.Rollback()
+
+	var (
+		failureCount int
+		lastDuration int
+		updatedAt    time.Time
+	)
+	stmt := `SELECT failure_count, last_lockout_duration_seconds, updated_at FROM login_attempts WHERE email = $1 FOR UPDATE`
+	err = tx.QueryRow(stmt, email).Scan(&failureCount, &lastDuration, &updatedAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		failureCount, lastDuration = 0, 0
+	case err != nil:
+		return false, time.Time{}, err
+	case time.Since(updatedAt) > window:
+		// The previous failures aged out of the window; start over.
+		failureCount = 0
+	}
+	failureCount++
+
+	var lockedUntil time.Time
+	if failureCount >= threshold {
+		duration := base
+		if lastDuration > 0 {
+			if doubled := time.Duration(lastDuration) * time.Second * 2; doubled < max {
+				duration = doubled
+			} else {
+				duration = max
+			}
+		}
+		lockedUntil = time.Now().Add(duration)
+		lastDuration = int(duration.Seconds())
+		failureCount = 0
+	}
+
+	var lockedUntilArg interface{}
+	if !lockedUntil.IsZero() {
+		lockedUntilArg = lockedUntil
+	}
+
+	upsert := `INSERT INTO login_attempts (email, failure_count, locked_until, last_lockout_duration_seconds, updated_at)
+			   VALUES ($1, $2, $3, $4, now())
+			   ON CONFLICT (email) DO UPDATE
+			   SET failure_count = $2, locked_until = $3, last_lockout_duration_seconds = $4, updated_at = now()`
+	if _, err := tx.Exec(upsert, email, failureCount, lockedUntilArg, lastDuration); err != nil {
+		return false, time.Time{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, time.Time{}, err
+	}
+
+	return !lockedUntil.IsZero(), lockedUntil, nil
+}
+
+// RecordSuccess clears email's failure count and lockout.
+func (m *LoginAttemptModel) RecordSuccess(email string) error {
+	_, err := m.DB.Exec(`DELETE FROM login_attempts WHERE email = $1`, email)
+	return err
+}
@@ -0,0 +1,306 @@
+package postgres
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/asankov/gira/pkg/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrEmailAlreadyExists is returned when trying to insert a user
+	// whose email is already taken by another user.
+	ErrEmailAlreadyExists = errors.New("user with this email already exists")
+	// ErrUsernameAlreadyExists is returned when trying to insert a user
+	// whose username is already taken by another user.
+	ErrUsernameAlreadyExists = errors.New("user with this username already exists")
+
+	// ErrInvalidCredentials is returned when the supplied email/password
+	// combination does not match a user, or the user is not verified.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+
+	// ErrTokenExpired is returned when a refresh/reset/verification token
+	// is found, but has already expired, or is a refresh token that was
+	// already revoked.
+	ErrTokenExpired = errors.New("token has expired or has already been used")
+	// ErrTokenNotFound is returned when no token matches the supplied value.
+	// A reset or verification token that was already used is reported
+	// this way too.
+	ErrTokenNotFound = errors.New("token not found")
+
+	// refreshTokenTTL is how long a refresh token remains valid for.
+	refreshTokenTTL = 30 * 24 * time.Hour
+	// passwordResetTokenTTL is how long a password reset token remains valid for.
+	passwordResetTokenTTL = 1 * time.Hour
+	// verificationTokenTTL is how long a verification token remains valid for.
+	verificationTokenTTL = 24 * time.Hour
+)
+
+// UserModel wraps a sql.DB connection pool and exposes
+// the operations needed to manage users.
+type UserModel struct {
+	DB *sql.DB
+}
+
+// Insert creates a new user, hashing its password before persisting it.
+func (m *UserModel) Insert(user *models.User) (*models.User, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := `INSERT INTO users (username, email, hashed_password, verified)
+			 VALUES ($1, $2, $3, false)
+			 RETURNING id`
+
+	var id string
+	if err := m.DB.QueryRow(stmt, user.Username, user.Email, hashedPassword).Scan(&id); err != nil {
+		switch {
+		case isUniqueViolation(err, "users_email_key"):
+			return nil, ErrEmailAlreadyExists
+		case isUniqueViolation(err, "users_username_key"):
+			return nil, ErrUsernameAlreadyExists
+		default:
+			return nil, err
+		}
+	}
+
+	return &models.User{
+		ID:       id,
+		Username: user.Username,
+		Email:    user.Email,
+	}, nil
+}
+
+// Authenticate verifies that a user with the given email and password exists
+// and is verified, returning it if so.
+func (m *UserModel) Authenticate(email, password string) (*models.User, error) {
+	var user models.User
+	stmt := `SELECT id, username, email, hashed_password, verified FROM users WHERE email = $1`
+	row := m.DB.QueryRow(stmt, email)
+	if err := row.Scan(&user.ID, &user.Username, &user.Email, &user.HashedPassword, &user.Verified); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword(user.HashedPassword, []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if !user.Verified {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &user, nil
+}
+
+// SaveRefreshToken persists the hash of rawToken for the given user,
+// so it can later be exchanged for a new access token via RefreshToken.
+func (m *UserModel) SaveRefreshToken(userID, rawToken string) error {
+	stmt := `INSERT INTO refresh_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3)`
+	_, err := m.DB.Exec(stmt, userID, hashToken(rawToken), time.Now().Add(refreshTokenTTL))
+	return err
+}
+
+// RefreshToken looks up the user for rawToken, and rotates it -
+// the old token is revoked and newRawToken is saved in its place.
+func (m *UserModel) RefreshToken(rawToken, newRawToken string) (*models.User, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var (
+		user      models.User
+		expiresAt time.Time
+		revokedAt sql.NullTime
+	)
+	stmt := `SELECT u.id, u.username, u.email, u.verified, rt.expires_at, rt.revoked_at
+			 FROM refresh_tokens rt JOIN users u ON u.id = rt.user_id
+			 WHERE rt.token_hash = $1
+			 FOR UPDATE`
+	row := tx.QueryRow(stmt, hashToken(rawToken))
+	if err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Verified, &expiresAt, &revokedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+
+	if revokedAt.Valid || time.Now().After(expiresAt) {
+		return nil, ErrTokenExpired
+	}
+
+	if _, err := tx.Exec(`UPDATE refresh_tokens SET revoked_at = now() WHERE token_hash = $1`, hashToken(rawToken)); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`INSERT INTO refresh_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3)`,
+		user.ID, hashToken(newRawToken), time.Now().Add(refreshTokenTTL)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// RevokeRefreshToken marks rawToken as revoked, so it can no longer be used to
+// obtain a new access token.
+func (m *UserModel) RevokeRefreshToken(rawToken string) error {
+	stmt := `UPDATE refresh_tokens SET revoked_at = now() WHERE token_hash = $1 AND revoked_at IS NULL`
+	res, err := m.DB.Exec(stmt, hashToken(rawToken))
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+// CreatePasswordResetToken generates and persists a password reset token for
+// the user with the given email, returning the raw token to be emailed to them.
+func (m *UserModel) CreatePasswordResetToken(email string) (string, error) {
+	var userID string
+	if err := m.DB.QueryRow(`SELECT id FROM users WHERE email = $1`, email).Scan(&userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrTokenNotFound
+		}
+		return "", err
+	}
+
+	rawToken, err := newRawToken()
+	if err != nil {
+		return "", err
+	}
+
+	stmt := `INSERT INTO password_reset_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3)`
+	if _, err := m.DB.Exec(stmt, userID, hashToken(rawToken), time.Now().Add(passwordResetTokenTTL)); err != nil {
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+// ResetPassword consumes rawToken and sets newPassword as the user's password.
+func (m *UserModel) ResetPassword(rawToken, newPassword string) error {
+	var (
+		userID    string
+		expiresAt time.Time
+	)
+	stmt := `SELECT user_id, expires_at FROM password_reset_tokens WHERE token_hash = $1 AND used_at IS NULL`
+	if err := m.DB.QueryRow(stmt, hashToken(rawToken)).Scan(&userID, &expiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTokenNotFound
+		}
+		return err
+	}
+	if time.Now().After(expiresAt) {
+		return ErrTokenExpired
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE users SET hashed_password = $1 WHERE id = $2`, hashedPassword, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE password_reset_tokens SET used_at = now() WHERE token_hash = $1`, hashToken(rawToken)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateVerificationToken generates and persists an email-verification token
+// for the given user, returning the raw token to be emailed to them.
+func (m *UserModel) CreateVerificationToken(userID string) (string, error) {
+	rawToken, err := newRawToken()
+	if err != nil {
+		return "", err
+	}
+
+	stmt := `INSERT INTO verification_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3)`
+	if _, err := m.DB.Exec(stmt, userID, hashToken(rawToken), time.Now().Add(verificationTokenTTL)); err != nil {
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+// VerifyUser consumes rawToken, marking the corresponding user as verified.
+func (m *UserModel) VerifyUser(rawToken string) error {
+	var (
+		userID    string
+		expiresAt time.Time
+	)
+	stmt := `SELECT user_id, expires_at FROM verification_tokens WHERE token_hash = $1 AND used_at IS NULL`
+	if err := m.DB.QueryRow(stmt, hashToken(rawToken)).Scan(&userID, &expiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTokenNotFound
+		}
+		return err
+	}
+	if time.Now().After(expiresAt) {
+		return ErrTokenExpired
+	}
+
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE users SET verified = true WHERE id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE verification_tokens SET used_at = now() WHERE token_hash = $1`, hashToken(rawToken)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// hashToken returns the hex-encoded SHA-256 hash of token, which is what
+// gets persisted instead of the raw value.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newRawToken returns a cryptographically random, URL-safe token.
+func newRawToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// isUniqueViolation reports whether err is a Postgres unique_violation
+// on the given constraint name.
+func isUniqueViolation(err error, constraint string) bool {
+	return err != nil && strings.Contains(err.Error(), "unique") && strings.Contains(err.Error(), constraint)
+}